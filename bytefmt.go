@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// byteUnit is one magnitude step of a byte-rate scale.
+type byteUnit struct {
+	suffix string
+	size   float64
+}
+
+// iecUnits and siUnits scale a byte rate by powers of 1024 and 1000
+// respectively, largest magnitude first.
+var (
+	iecUnits = []byteUnit{
+		{"tb", 1024 * 1024 * 1024 * 1024},
+		{"gb", 1024 * 1024 * 1024},
+		{"mb", 1024 * 1024},
+		{"kb", 1024},
+		{"b", 1},
+	}
+	siUnits = []byteUnit{
+		{"TB", 1e12},
+		{"GB", 1e9},
+		{"MB", 1e6},
+		{"kB", 1e3},
+		{"B", 1},
+	}
+)
+
+// netFmt configures how fixed renders net byte rates.
+var netFmt = struct {
+	SI       bool // true: SI (base 1000) units; false: IEC (base 1024)
+	Decimals int  // decimal places
+	Width    int  // total field width incl. suffix, 0 to disable padding
+}{
+	SI:       false,
+	Decimals: 1,
+	Width:    7,
+}
+
+// formatBytes renders a byte rate as a human-readable string with a unit
+// suffix, scaling up through KiB/MiB/GiB/TiB (or the SI equivalents) as
+// needed. Unlike the old hand-rolled formatter, it never runs out of units
+// and returns "ERR" for legitimate multi-GiB/s rates on fast links.
+func formatBytes(rate int64) string {
+	units := iecUnits
+	if netFmt.SI {
+		units = siUnits
+	}
+
+	unit := units[len(units)-1] // smallest unit as the default for rate 0
+	val := float64(rate)
+	for _, u := range units {
+		if val >= u.size {
+			unit = u
+			break
+		}
+	}
+
+	formatted := fmt.Sprintf("%.*f", netFmt.Decimals, val/unit.size)
+	formatted = strings.Replace(formatted, ".", floatSeparator, 1) + unit.suffix
+
+	if netFmt.Width > 0 {
+		return fmt.Sprintf("%*s", netFmt.Width, formatted)
+	}
+	return formatted
+}
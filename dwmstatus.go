@@ -1,23 +1,17 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"os"
 	"os/exec"
-	"runtime"
-	"strconv"
+	"regexp"
 	"strings"
-	"time"
+
+	"github.com/jishnusen/dwmstatus/stats"
 )
 
 const (
-	bpsSign   = "b"
-	kibpsSign = "kb"
-	mibpsSign = "mb"
-
 	unpluggedSign = "☢"
 	pluggedSign   = "⚡"
 
@@ -27,234 +21,228 @@ const (
 	netReceivedSign    = "RX "
 	netTransmittedSign = "TX "
 
+	diskReadSign  = "↓"
+	diskWriteSign = "↑"
+
+	uptimeSign = "up "
+	tempSign   = "TEMP "
+
 	floatSeparator = ""
 	dateSeparator  = ""
 	fieldSeparator = " | "
 )
 
 var (
-	netDevs = map[string]struct{}{
-		"enp2s6:": {},
-		"wlan0:":  {},
-	}
-	cores = runtime.NumCPU() // count of cores to scale cpu usage
-	rxOld = 0
-	txOld = 0
+	netProvider    = stats.NewNet()
+	cpuProvider    = stats.NewCPU()
+	memProvider    = stats.NewMem()
+	powerProvider  = stats.NewPower()
+	diskProvider   = stats.NewDisk()
+	uptimeProvider = stats.NewUptime()
+	tempProvider   = stats.NewTemp()
+
+	rxOld int64
+	txOld int64
 )
 
-// fixed builds a fixed width string with given pre- and fitting suffix
-func fixed(pre string, rate int) string {
-	if rate < 0 {
-		return pre + " ERR"
-	}
+// Per-segment warn/crit thresholds for the theme coloring in colored() and
+// updatePower/updateTemp/updateDiskUse.
+var (
+	cpuWarnPct, cpuCritPct   = 70.0, 90.0
+	memWarnPct, memCritPct   = 70.0, 90.0
+	battWarnPct, battCritPct = 10.0, 5.0
+	tempWarnC, tempCritC     = 70.0, 85.0
+	diskWarnPct, diskCritPct = 80.0, 95.0
+)
 
-	var spd = float32(rate)
-	var suf = bpsSign // default: display as B/s
-
-	switch {
-	case spd >= (1000 * 1024 * 1024): // > 999 MiB/s
-		return "" + pre + "ERR"
-	case spd >= (1000 * 1024): // display as MiB/s
-		spd /= (1024 * 1024)
-		suf = mibpsSign
-		pre = "" + pre + ""
-	case spd >= 1000: // display as KiB/s
-		spd /= 1024
-		suf = kibpsSign
-	}
+// diskMounts lists the mount points reported by the disk module, one
+// segment per entry.
+var diskMounts = []string{"/"}
 
-	var formated = ""
-	if spd >= 100 {
-		formated = fmt.Sprintf("%3.0f", spd)
-	} else if spd >= 10 {
-		formated = fmt.Sprintf("%4.1f", spd)
-	} else {
-		formated = fmt.Sprintf(" %3.1f", spd)
+// fixed renders a byte rate with the given prefix, delegating the actual
+// unit scaling to formatBytes.
+func fixed(pre string, rate int64) string {
+	if rate < 0 {
+		return pre + " ERR"
 	}
-	return pre + strings.Replace(formated, ".", floatSeparator, 1) + suf
+	return pre + formatBytes(rate)
 }
 
-// updateNetUse reads current transfer rates of certain network interfaces
+// updateNetUse reads current transfer rates across the platform's network
+// interfaces of interest, dispatching through stats.NetProvider
 func updateNetUse() string {
-	file, err := os.Open("/proc/net/dev")
-	defer file.Close()
+	rxNow, txNow, err := netProvider.NetUse()
 	if err != nil {
 		return netReceivedSign + " ERR " + netTransmittedSign + " ERR"
 	}
 
-	var void = 0 // target for unused values
-	var dev, rx, tx, rxNow, txNow = "", 0, 0, 0, 0
-	var scanner = bufio.NewScanner(file)
-	for scanner.Scan() {
-		_, err = fmt.Sscanf(scanner.Text(), "%s %d %d %d %d %d %d %d %d %d",
-			&dev, &rx, &void, &void, &void, &void, &void, &void, &void, &tx)
-		if _, ok := netDevs[dev]; ok {
-			rxNow += rx
-			txNow += tx
-		}
-	}
-
 	defer func() { rxOld, txOld = rxNow, txNow }()
 	return fmt.Sprintf("%s %s", fixed(netReceivedSign, rxNow-rxOld), fixed(netTransmittedSign, txNow-txOld))
 }
 
-// colored surrounds the percentage with color escapes if it is >= 70
-func colored(icon string, percentage int) string {
-	if percentage >= 100 {
-		return fmt.Sprintf("%s%3d", icon, percentage)
-	} else if percentage >= 70 {
-		return fmt.Sprintf("%s%3d", icon, percentage)
-	}
-	return fmt.Sprintf("%s%3d", icon, percentage)
+// colored wraps icon+value in the active theme's OK/WARN/CRIT color,
+// chosen by comparing value against warn/crit (a high value is bad).
+func colored(icon string, value int, warn, crit float64) string {
+	text := fmt.Sprintf("%s%3d", icon, value)
+	return theme.apply(text, severityHigh(float64(value), warn, crit))
 }
 
-// updatePower reads the current battery and power plug status
+// updatePower reads the current battery and power plug status, dispatching
+// through stats.PowerProvider. A low charge is bad, so severity runs the
+// opposite way from colored()'s CPU/MEM/disk segments.
 func updatePower() string {
-	const powerSupply = "/sys/class/power_supply/"
-	var enFull, enNow, enPerc int = 0, 0, 0
-	var plugged, err = ioutil.ReadFile(powerSupply + "ADP1/online")
+	enPerc, plugged, err := powerProvider.PowerUse()
 	if err != nil {
 		return err.Error()
 	}
-	batts, err := ioutil.ReadDir(powerSupply)
-	if err != nil {
-		return err.Error()
-	}
-
-	readval := func(name, field string) int {
-		var path = powerSupply + name + "/"
-		var file []byte
-		if tmp, err := ioutil.ReadFile(path + "energy_" + field); err == nil {
-			file = tmp
-		} else if tmp, err := ioutil.ReadFile(path + "charge_" + field); err == nil {
-			file = tmp
-		} else {
-			return 0
-		}
-
-		if ret, err := strconv.Atoi(strings.TrimSpace(string(file))); err == nil {
-			return ret
-		}
-		return 0
-	}
 
-	for _, batt := range batts {
-		name := batt.Name()
-		if !strings.HasPrefix(name, "BAT") {
-			continue
-		}
-
-		enFull += readval(name, "full")
-		enNow += readval(name, "now")
-	}
-
-	if enFull == 0 { // Battery found but no readable full file.
-		return "ERR"
-	}
-
-	enPerc = enNow * 100 / enFull
 	var icon = unpluggedSign
-	if string(plugged) == "1\n" {
+	if plugged {
 		icon = pluggedSign
 	}
 
-	if enPerc <= 5 {
-		return fmt.Sprintf("%s%3d", icon, enPerc) + "%"
-	} else if enPerc <= 10 {
-		return fmt.Sprintf("%s%3d", icon, enPerc) + "%"
-	}
-	return fmt.Sprintf("%s%3d", icon, enPerc) + "%"
+	text := fmt.Sprintf("%s%3d%%", icon, enPerc)
+	return theme.apply(text, severityLow(float64(enPerc), battWarnPct, battCritPct))
 }
 
-// updateCPUUse reads the last minute sysload and scales it to the core count
+// updateCPUUse reads the current CPU load, scaled to [0, 100], dispatching
+// through stats.CPUProvider
 func updateCPUUse() string {
-	var load float32
-	var loadavg, err = ioutil.ReadFile("/proc/loadavg")
+	load, err := cpuProvider.CPUUse()
 	if err != nil {
 		return cpuSign + "ERR"
 	}
-	_, err = fmt.Sscanf(string(loadavg), "%f", &load)
+	return colored(cpuSign, int(load), cpuWarnPct, cpuCritPct)
+}
+
+// cpuPerCoreDisplay chooses whether updateCPUPct renders an aggregate
+// number or a per-core breakdown, e.g. "CPU 42 [30 55 20 60]".
+var cpuPerCoreDisplay = false
+
+// updateCPUPct reports true CPU utilization sampled from OS counters (e.g.
+// /proc/stat jiffies) rather than load-average, falling back to
+// updateCPUUse when the provider doesn't support it or the sample can't be
+// read.
+func updateCPUPct() string {
+	pct, ok := cpuProvider.(stats.CPUPctProvider)
+	if !ok {
+		return updateCPUUse()
+	}
+
+	agg, cores, err := pct.CPUPct(cpuPerCoreDisplay)
 	if err != nil {
 		return cpuSign + "ERR"
 	}
-	return colored(cpuSign, int(load*100.0/float32(cores)))
+
+	if cpuPerCoreDisplay && len(cores) > 0 {
+		parts := make([]string, len(cores))
+		for i, c := range cores {
+			parts[i] = fmt.Sprintf("%d", int(c))
+		}
+		return colored(cpuSign, int(agg), cpuWarnPct, cpuCritPct) + " [" + strings.Join(parts, " ") + "]"
+	}
+	return colored(cpuSign, int(agg), cpuWarnPct, cpuCritPct)
 }
 
-// updateMemUse reads the memory used by applications and scales to [0, 100]
+// updateMemUse reads the memory used by applications, scaled to [0, 100],
+// dispatching through stats.MemProvider
 func updateMemUse() string {
-	var file, err = os.Open("/proc/meminfo")
-	defer file.Close()
+	percent, err := memProvider.MemUse()
 	if err != nil {
 		return memSign + "ERR"
 	}
+	return colored(memSign, percent, memWarnPct, memCritPct)
+}
 
-	// done must equal the flag combination (0001 | 0010 | 0100 | 1000) = 15
-	var total, used, done = 0, 0, 0
-	for info := bufio.NewScanner(file); done != 15 && info.Scan(); {
-		var prop, val = "", 0
-		if _, err = fmt.Sscanf(info.Text(), "%s %d", &prop, &val); err != nil {
-			return memSign + "ERR"
-		}
-		switch prop {
-		case "MemTotal:":
-			total = val
-			used += val
-			done |= 1
-		case "MemFree:":
-			used -= val
-			done |= 2
-		case "Buffers:":
-			used -= val
-			done |= 4
-		case "Cached:":
-			used -= val
-			done |= 8
-		}
+// updateDiskUse reports free space and read/write throughput for mount,
+// dispatching through stats.DiskProvider, e.g. "/ 42% | ↓1.2M ↑300K".
+// Removed or unmounted paths between ticks just surface as "ERR" rather
+// than taking down the whole bar.
+func updateDiskUse(mount string) string {
+	stat, err := diskProvider.DiskUse(mount)
+	if err != nil {
+		return mount + " ERR"
 	}
-	return colored(memSign, used*100/total)
+
+	pct := theme.apply(fmt.Sprintf("%d%%", stat.UsedPercent), severityHigh(float64(stat.UsedPercent), diskWarnPct, diskCritPct))
+	return fmt.Sprintf("%s %s | %s %s", mount, pct,
+		fixed(diskReadSign, stat.ReadRate), fixed(diskWriteSign, stat.WriteRate))
 }
 
-func IsEmpty(name string) bool {
-	f, err := os.Open(name)
+// updateUptime reports how long the system has been running, dispatching
+// through stats.UptimeProvider, e.g. "up 3d 4h".
+func updateUptime() string {
+	d, err := uptimeProvider.Uptime()
 	if err != nil {
-		return false
+		return uptimeSign + "ERR"
 	}
-	defer f.Close()
 
-	// read in ONLY one file
-	_, err = f.Readdir(1)
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	return fmt.Sprintf("%s%dd %dh", uptimeSign, days, hours)
+}
 
-	// and if the file is EOF... well, the dir is empty.
-	if err == io.EOF {
-		return true
+// updateTemp reports the CPU package temperature, dispatching through
+// stats.TempProvider.
+func updateTemp() string {
+	c, err := tempProvider.Temp()
+	if err != nil {
+		return tempSign + "ERR"
 	}
-	return false
+
+	text := fmt.Sprintf("%s%3.0f°C", tempSign, c)
+	return theme.apply(text, severityHigh(c, tempWarnC, tempCritC))
 }
 
-// main updates the dwm statusbar every second
+// main assembles the active modules, starts their scheduler, and rewrites
+// the dwm root name whenever any module produces new output.
 func main() {
-	var status = []string{}
-	for {
-		if !IsEmpty("/sys/class/power_supply/") {
-			status = []string{
-				updateNetUse(),
-				updateCPUUse() + "%",
-				updateMemUse() + "%",
-				updatePower(),
-				time.Now().Local().Format("Monday January 02  3:04:05 PM"),
-			}
-		} else {
-			status = []string{
-				updateNetUse(),
-				updateCPUUse() + "%",
-				updateMemUse() + "%",
-				time.Now().Local().Format("Monday January 02  3:04:05 PM"),
-			}
+	pango := flag.Bool("pango", false, "emit pango color markup instead of dwm color-escape bytes")
+	netSI := flag.Bool("net-si", netFmt.SI, "render net byte rates in SI (base 1000) units instead of IEC (base 1024)")
+	netDecimals := flag.Int("net-decimals", netFmt.Decimals, "decimal places for net byte rates")
+	netWidth := flag.Int("net-width", netFmt.Width, "fixed field width for net byte rates, 0 to disable padding")
+	netInclude := flag.String("net-include", "", "regex of network interfaces to report on, overriding the built-in exclusions")
+	netExclude := flag.String("net-exclude", "", "regex of network interfaces to exclude, applied on top of the built-in exclusions")
+	cpuPerCore := flag.Bool("cpu-per-core", cpuPerCoreDisplay, "render a per-core breakdown alongside the aggregate CPU percentage")
+	flag.Parse()
+	theme.Pango = *pango
+
+	netFmt.SI = *netSI
+	netFmt.Decimals = *netDecimals
+	netFmt.Width = *netWidth
+	if *netInclude != "" {
+		re, err := regexp.Compile(*netInclude)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "dwmstatus: invalid -net-include regex: %v\n", err)
+			os.Exit(1)
 		}
-		exec.Command("xsetroot", "-name", strings.Join(status, fieldSeparator)).Run()
+		stats.NetIncludeRegex = re
+	}
+	if *netExclude != "" {
+		re, err := regexp.Compile(*netExclude)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "dwmstatus: invalid -net-exclude regex: %v\n", err)
+			os.Exit(1)
+		}
+		stats.NetExcludeRegex = re
+	}
+	cpuPerCoreDisplay = *cpuPerCore
+
+	modules := []Module{netModule{}, cpuModule{}, memModule{}}
+	for _, mount := range diskMounts {
+		modules = append(modules, diskModule{mount: mount})
+	}
+	modules = append(modules, uptimeModule{}, tempModule{})
+	if _, _, err := powerProvider.PowerUse(); err == nil {
+		modules = append(modules, powerModule{})
+	}
+	modules = append(modules, clockModule{})
+
+	sched := NewScheduler(modules)
+	sched.Run(modules)
+	go handleSignals(sched, modules)
 
-		// sleep until beginning of next second
-		var now = time.Now()
-		time.Sleep(now.Truncate(time.Second).Add(time.Second).Sub(now))
+	for range sched.changed {
+		exec.Command("xsetroot", "-name", sched.Render()).Run()
 	}
 }
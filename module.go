@@ -0,0 +1,11 @@
+package main
+
+import "time"
+
+// Module is a single segment of the status bar. Each module owns its
+// refresh cadence and is responsible for rendering its own text.
+type Module interface {
+	Name() string
+	Update() (string, error)
+	Interval() time.Duration
+}
@@ -0,0 +1,65 @@
+package main
+
+import "time"
+
+// netModule reports network transfer rates every second.
+type netModule struct{}
+
+func (netModule) Name() string            { return "net" }
+func (netModule) Interval() time.Duration { return time.Second }
+func (netModule) Update() (string, error) { return updateNetUse(), nil }
+
+// cpuModule reports scaled sysload every second.
+type cpuModule struct{}
+
+func (cpuModule) Name() string            { return "cpu" }
+func (cpuModule) Interval() time.Duration { return time.Second }
+func (cpuModule) Update() (string, error) { return updateCPUPct() + "%", nil }
+
+// memModule reports memory usage every second.
+type memModule struct{}
+
+func (memModule) Name() string            { return "mem" }
+func (memModule) Interval() time.Duration { return time.Second }
+func (memModule) Update() (string, error) { return updateMemUse() + "%", nil }
+
+// diskModule reports capacity and I/O throughput for a single mount point.
+type diskModule struct {
+	mount string
+}
+
+func (d diskModule) Name() string            { return "disk:" + d.mount }
+func (diskModule) Interval() time.Duration   { return time.Second }
+func (d diskModule) Update() (string, error) { return updateDiskUse(d.mount), nil }
+
+// uptimeModule reports how long the system has been running. This barely
+// changes minute to minute, so it only needs an occasional tick.
+type uptimeModule struct{}
+
+func (uptimeModule) Name() string            { return "uptime" }
+func (uptimeModule) Interval() time.Duration { return 60 * time.Second }
+func (uptimeModule) Update() (string, error) { return updateUptime(), nil }
+
+// tempModule reports CPU package temperature every few seconds.
+type tempModule struct{}
+
+func (tempModule) Name() string            { return "temp" }
+func (tempModule) Interval() time.Duration { return 5 * time.Second }
+func (tempModule) Update() (string, error) { return updateTemp(), nil }
+
+// powerModule reports battery/plug status. Battery levels move slowly, so
+// this only needs to tick once a minute between forced refreshes.
+type powerModule struct{}
+
+func (powerModule) Name() string            { return "power" }
+func (powerModule) Interval() time.Duration { return 60 * time.Second }
+func (powerModule) Update() (string, error) { return updatePower(), nil }
+
+// clockModule reports the current local time every second.
+type clockModule struct{}
+
+func (clockModule) Name() string            { return "clock" }
+func (clockModule) Interval() time.Duration { return time.Second }
+func (clockModule) Update() (string, error) {
+	return time.Now().Local().Format("Monday January 02  3:04:05 PM"), nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scheduler runs a set of Modules on their own tickers, keeps the most
+// recently rendered text of each one, and wakes the aggregator in main
+// whenever any of them changes.
+type Scheduler struct {
+	order   []string
+	mu      sync.Mutex
+	values  map[string]string
+	changed chan struct{}
+	forces  map[string]chan struct{}
+}
+
+// NewScheduler builds a Scheduler for modules, preserving their order for
+// rendering the final status line.
+func NewScheduler(modules []Module) *Scheduler {
+	s := &Scheduler{
+		values:  make(map[string]string, len(modules)),
+		changed: make(chan struct{}, 1),
+		forces:  make(map[string]chan struct{}, len(modules)),
+	}
+	for _, m := range modules {
+		s.order = append(s.order, m.Name())
+		s.forces[m.Name()] = make(chan struct{}, 1)
+	}
+	return s
+}
+
+// Run starts one goroutine per module that refreshes it on its declared
+// Interval, or immediately when ForceRefresh(es) for it.
+func (s *Scheduler) Run(modules []Module) {
+	for _, m := range modules {
+		go s.runModule(m)
+	}
+}
+
+func (s *Scheduler) runModule(m Module) {
+	s.refresh(m)
+
+	ticker := time.NewTicker(m.Interval())
+	defer ticker.Stop()
+
+	force := s.forces[m.Name()]
+	for {
+		select {
+		case <-ticker.C:
+			s.refresh(m)
+		case <-force:
+			s.refresh(m)
+		}
+	}
+}
+
+func (s *Scheduler) refresh(m Module) {
+	out, err := m.Update()
+	if err != nil {
+		out = "ERR"
+	}
+
+	s.mu.Lock()
+	s.values[m.Name()] = out
+	s.mu.Unlock()
+
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// ForceRefresh triggers an immediate refresh of the named module, bypassing
+// its ticker. Unknown names are ignored.
+func (s *Scheduler) ForceRefresh(name string) {
+	if force, ok := s.forces[name]; ok {
+		select {
+		case force <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// ForceRefreshAll triggers an immediate refresh of every module.
+func (s *Scheduler) ForceRefreshAll() {
+	for name := range s.forces {
+		s.ForceRefresh(name)
+	}
+}
+
+// Render joins the most recently computed value of every module, in
+// declaration order, into a single status line.
+func (s *Scheduler) Render() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parts := make([]string, len(s.order))
+	for i, name := range s.order {
+		parts[i] = s.values[name]
+	}
+	return strings.Join(parts, fieldSeparator)
+}
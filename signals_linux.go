@@ -0,0 +1,45 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// rtmin is SIGRTMIN on Linux. Per-module force-refresh signals are assigned
+// sequentially from here, in module order.
+const rtmin = syscall.Signal(34)
+
+// maxRTSignals bounds how many modules can be addressed individually; the
+// real-time signal range only goes up to SIGRTMAX (typically rtmin+32).
+const maxRTSignals = 32
+
+// handleSignals listens for SIGUSR1 (refresh every module) and
+// SIGRTMIN+N (refresh only the Nth module), so an expensive or
+// externally-driven module -- volume, MPD, a manual trigger -- can be
+// forced to update without waiting for its next tick.
+func handleSignals(sched *Scheduler, modules []Module) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGUSR1)
+	for i := range modules {
+		if i >= maxRTSignals {
+			break
+		}
+		signal.Notify(sigs, rtmin+syscall.Signal(i))
+	}
+
+	for sig := range sigs {
+		if sig == syscall.SIGUSR1 {
+			sched.ForceRefreshAll()
+			continue
+		}
+		if s, ok := sig.(syscall.Signal); ok {
+			if idx := int(s - rtmin); idx >= 0 && idx < len(modules) {
+				sched.ForceRefresh(modules[idx].Name())
+			}
+		}
+	}
+}
@@ -0,0 +1,22 @@
+//go:build !windows && !linux
+// +build !windows,!linux
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// handleSignals listens for SIGUSR1 (refresh every module). The
+// SIGRTMIN+N per-module force-refresh used on linux isn't available here:
+// SIGRTMIN's numbering and range aren't portable across the BSDs/darwin,
+// so this platform only gets the whole-bar refresh.
+func handleSignals(sched *Scheduler, modules []Module) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGUSR1)
+	for range sigs {
+		sched.ForceRefreshAll()
+	}
+}
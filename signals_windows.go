@@ -0,0 +1,9 @@
+//go:build windows
+// +build windows
+
+package main
+
+// handleSignals is a no-op on windows: SIGUSR1 and the real-time signal
+// range used for per-module force-refresh on unix don't exist there.
+// Modules still refresh on their own declared Interval.
+func handleSignals(sched *Scheduler, modules []Module) {}
@@ -0,0 +1,146 @@
+//go:build !linux
+// +build !linux
+
+package stats
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/mem"
+	gnet "github.com/shirou/gopsutil/v3/net"
+)
+
+type gopsutilNet struct{}
+
+// NewNet returns the gopsutil-backed NetProvider. Interfaces are discovered
+// per call rather than hard-coded; see netDevWanted.
+func NewNet() NetProvider { return gopsutilNet{} }
+
+func (gopsutilNet) NetUse() (rx, tx int64, err error) {
+	counters, err := gnet.IOCounters(true) // true: report each interface individually
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, c := range counters {
+		if !netDevWanted(c.Name) {
+			continue
+		}
+		rx += int64(c.BytesRecv)
+		tx += int64(c.BytesSent)
+	}
+	return rx, tx, nil
+}
+
+type gopsutilCPU struct{}
+
+// NewCPU returns the gopsutil-backed CPUProvider.
+func NewCPU() CPUProvider { return gopsutilCPU{} }
+
+func (gopsutilCPU) CPUUse() (float64, error) {
+	percents, err := cpu.Percent(0, false) // false: aggregate across cores
+	if err != nil {
+		return 0, err
+	}
+	if len(percents) == 0 {
+		return 0, errors.New("no CPU usage reported")
+	}
+	return percents[0], nil
+}
+
+type gopsutilMem struct{}
+
+// NewMem returns the gopsutil-backed MemProvider.
+func NewMem() MemProvider { return gopsutilMem{} }
+
+func (gopsutilMem) MemUse() (int, error) {
+	v, err := mem.VirtualMemory()
+	if err != nil {
+		return 0, err
+	}
+	return int(v.UsedPercent), nil
+}
+
+type gopsutilPower struct{}
+
+// NewPower returns the gopsutil-backed PowerProvider.
+func NewPower() PowerProvider { return gopsutilPower{} }
+
+// PowerUse always errors: gopsutil has no battery API, so non-Linux builds
+// simply drop the power module rather than report garbage (see main()).
+func (gopsutilPower) PowerUse() (int, bool, error) {
+	return 0, false, errors.New("power reporting is not supported on this platform")
+}
+
+type gopsutilDisk struct{}
+
+// NewDisk returns the gopsutil-backed DiskProvider.
+func NewDisk() DiskProvider { return gopsutilDisk{} }
+
+func (gopsutilDisk) DiskUse(mount string) (DiskStat, error) {
+	var stat DiskStat
+
+	usage, err := disk.Usage(mount)
+	if err != nil {
+		clearDiskSample(mount)
+		return stat, err
+	}
+	stat.UsedPercent = int(usage.UsedPercent)
+
+	// gopsutil has no portable way to map a mount point to the counters
+	// for its backing device (unlike /proc/mounts+/proc/diskstats on
+	// Linux), so this sums throughput across every device it reports.
+	counters, err := disk.IOCounters()
+	if err != nil {
+		clearDiskSample(mount)
+		return stat, err
+	}
+	var readBytes, writeBytes int64
+	for _, c := range counters {
+		readBytes += int64(c.ReadBytes)
+		writeBytes += int64(c.WriteBytes)
+	}
+
+	stat.ReadRate, stat.WriteRate = diskRate(mount, readBytes, writeBytes)
+	return stat, nil
+}
+
+type gopsutilUptime struct{}
+
+// NewUptime returns the gopsutil-backed UptimeProvider.
+func NewUptime() UptimeProvider { return gopsutilUptime{} }
+
+func (gopsutilUptime) Uptime() (time.Duration, error) {
+	info, err := host.Info()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(info.Uptime) * time.Second, nil
+}
+
+type gopsutilTemp struct{}
+
+// NewTemp returns the gopsutil-backed TempProvider.
+func NewTemp() TempProvider { return gopsutilTemp{} }
+
+func (gopsutilTemp) Temp() (float64, error) {
+	sensors, err := host.SensorsTemperatures()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, s := range sensors {
+		key := strings.ToLower(s.SensorKey)
+		if strings.Contains(key, "package") || strings.Contains(key, "cpu") {
+			return s.Temperature, nil
+		}
+	}
+	if len(sensors) > 0 {
+		return sensors[0].Temperature, nil
+	}
+	return 0, errors.New("no temperature sensors reported")
+}
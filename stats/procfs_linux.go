@@ -0,0 +1,380 @@
+//go:build linux
+// +build linux
+
+package stats
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+type procfsNet struct{}
+
+// NewNet returns the procfs-backed NetProvider. Interfaces are discovered
+// from /proc/net/dev each call rather than hard-coded; see netDevWanted.
+func NewNet() NetProvider { return procfsNet{} }
+
+func (procfsNet) NetUse() (rx, tx int64, err error) {
+	file, err := os.Open("/proc/net/dev")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	var void int64
+	var dev string
+	var scanner = bufio.NewScanner(file)
+	for scanner.Scan() {
+		var r, t int64
+		fmt.Sscanf(scanner.Text(), "%s %d %d %d %d %d %d %d %d %d",
+			&dev, &r, &void, &void, &void, &void, &void, &void, &void, &t)
+		if netDevWanted(dev) {
+			rx += r
+			tx += t
+		}
+	}
+	return rx, tx, nil
+}
+
+type procfsCPU struct{}
+
+// NewCPU returns the procfs-backed CPUProvider.
+func NewCPU() CPUProvider { return procfsCPU{} }
+
+func (procfsCPU) CPUUse() (float64, error) {
+	var load float32
+	loadavg, err := ioutil.ReadFile("/proc/loadavg")
+	if err != nil {
+		return 0, err
+	}
+	if _, err := fmt.Sscanf(string(loadavg), "%f", &load); err != nil {
+		return 0, err
+	}
+	return float64(load) * 100.0 / float64(runtime.NumCPU()), nil
+}
+
+// cpuTimes is one /proc/stat jiffy sample, used to compute a utilization
+// delta between two ticks.
+type cpuTimes struct {
+	user, nice, system, idle, iowait, irq, softirq, steal int64
+}
+
+func (t cpuTimes) total() int64 {
+	return t.user + t.nice + t.system + t.idle + t.iowait + t.irq + t.softirq + t.steal
+}
+
+var (
+	prevAggregate cpuTimes
+	prevCores     []cpuTimes
+	havePrevStat  bool
+)
+
+// readProcStat parses the "cpu" (aggregate) and "cpuN" (per-core) lines of
+// /proc/stat into jiffy samples.
+func readProcStat() (aggregate cpuTimes, cores []cpuTimes, err error) {
+	file, err := os.Open("/proc/stat")
+	if err != nil {
+		return cpuTimes{}, nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 9 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+
+		var vals [8]int64
+		for i := range vals {
+			vals[i], _ = strconv.ParseInt(fields[i+1], 10, 64)
+		}
+		t := cpuTimes{vals[0], vals[1], vals[2], vals[3], vals[4], vals[5], vals[6], vals[7]}
+
+		if fields[0] == "cpu" {
+			aggregate = t
+		} else {
+			cores = append(cores, t)
+		}
+	}
+	return aggregate, cores, scanner.Err()
+}
+
+// statDeltaPct turns two jiffy samples into a utilization percentage.
+func statDeltaPct(prev, cur cpuTimes) float64 {
+	totalDelta := cur.total() - prev.total()
+	if totalDelta <= 0 {
+		return 0
+	}
+	idleDelta := (cur.idle + cur.iowait) - (prev.idle + prev.iowait)
+	return (1 - float64(idleDelta)/float64(totalDelta)) * 100
+}
+
+// CPUPct reports true utilization sampled from /proc/stat jiffies since the
+// previous call, falling back to the loadavg-based CPUUse if /proc/stat
+// can't be read. The first call after startup has no prior sample to diff
+// against and reports 0.
+func (procfsCPU) CPUPct(perCore bool) (float64, []float64, error) {
+	aggregate, cores, err := readProcStat()
+	if err != nil {
+		pct, lerr := (procfsCPU{}).CPUUse()
+		return pct, nil, lerr
+	}
+
+	var aggPct float64
+	var corePcts []float64
+	if havePrevStat {
+		aggPct = statDeltaPct(prevAggregate, aggregate)
+		if perCore && len(cores) == len(prevCores) {
+			corePcts = make([]float64, len(cores))
+			for i := range cores {
+				corePcts[i] = statDeltaPct(prevCores[i], cores[i])
+			}
+		}
+	}
+
+	prevAggregate, prevCores, havePrevStat = aggregate, cores, true
+	return aggPct, corePcts, nil
+}
+
+type procfsMem struct{}
+
+// NewMem returns the procfs-backed MemProvider.
+func NewMem() MemProvider { return procfsMem{} }
+
+func (procfsMem) MemUse() (int, error) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	// done must equal the flag combination (0001 | 0010 | 0100 | 1000) = 15
+	var total, used, done = 0, 0, 0
+	for info := bufio.NewScanner(file); done != 15 && info.Scan(); {
+		var prop string
+		var val int
+		if _, err := fmt.Sscanf(info.Text(), "%s %d", &prop, &val); err != nil {
+			return 0, err
+		}
+		switch prop {
+		case "MemTotal:":
+			total = val
+			used += val
+			done |= 1
+		case "MemFree:":
+			used -= val
+			done |= 2
+		case "Buffers:":
+			used -= val
+			done |= 4
+		case "Cached:":
+			used -= val
+			done |= 8
+		}
+	}
+	return used * 100 / total, nil
+}
+
+type procfsPower struct{}
+
+// NewPower returns the procfs-backed PowerProvider.
+func NewPower() PowerProvider { return procfsPower{} }
+
+func (procfsPower) PowerUse() (int, bool, error) {
+	const powerSupply = "/sys/class/power_supply/"
+	plugged, err := ioutil.ReadFile(powerSupply + "ADP1/online")
+	if err != nil {
+		return 0, false, err
+	}
+	batts, err := ioutil.ReadDir(powerSupply)
+	if err != nil {
+		return 0, false, err
+	}
+
+	readval := func(name, field string) int {
+		path := powerSupply + name + "/"
+		var file []byte
+		if tmp, err := ioutil.ReadFile(path + "energy_" + field); err == nil {
+			file = tmp
+		} else if tmp, err := ioutil.ReadFile(path + "charge_" + field); err == nil {
+			file = tmp
+		} else {
+			return 0
+		}
+		if ret, err := strconv.Atoi(strings.TrimSpace(string(file))); err == nil {
+			return ret
+		}
+		return 0
+	}
+
+	var enFull, enNow int
+	for _, batt := range batts {
+		name := batt.Name()
+		if !strings.HasPrefix(name, "BAT") {
+			continue
+		}
+		enFull += readval(name, "full")
+		enNow += readval(name, "now")
+	}
+	if enFull == 0 { // Battery found but no readable full file.
+		return 0, false, fmt.Errorf("no readable battery found in %s", powerSupply)
+	}
+
+	return enNow * 100 / enFull, string(plugged) == "1\n", nil
+}
+
+type procfsDisk struct{}
+
+// NewDisk returns the procfs-backed DiskProvider.
+func NewDisk() DiskProvider { return procfsDisk{} }
+
+func (procfsDisk) DiskUse(mount string) (DiskStat, error) {
+	var stat DiskStat
+
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(mount, &fs); err != nil {
+		clearDiskSample(mount)
+		return stat, err
+	}
+	total := uint64(fs.Blocks) * uint64(fs.Bsize)
+	free := uint64(fs.Bfree) * uint64(fs.Bsize)
+	if total > 0 {
+		stat.UsedPercent = int((total - free) * 100 / total)
+	}
+
+	dev, err := diskDeviceFor(mount)
+	if err != nil {
+		clearDiskSample(mount)
+		return stat, err
+	}
+	readSectors, writeSectors, err := readDiskStats(dev)
+	if err != nil {
+		clearDiskSample(mount)
+		return stat, err
+	}
+
+	// /proc/diskstats reports sectors, which are always 512 bytes
+	// regardless of the device's actual block size.
+	stat.ReadRate, stat.WriteRate = diskRate(mount, readSectors*512, writeSectors*512)
+	return stat, nil
+}
+
+// diskDeviceFor looks up the block device backing mount via /proc/mounts,
+// e.g. "/" -> "sda1", for correlating against /proc/diskstats.
+func diskDeviceFor(mount string) (string, error) {
+	file, err := os.Open("/proc/mounts")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[1] != mount {
+			continue
+		}
+		if !strings.HasPrefix(fields[0], "/dev/") {
+			return "", fmt.Errorf("mount %s is not backed by a device", mount)
+		}
+		return strings.TrimPrefix(fields[0], "/dev/"), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("mount point %s not found in /proc/mounts", mount)
+}
+
+// readDiskStats returns the cumulative sectors read/written for dev from
+// /proc/diskstats.
+func readDiskStats(dev string) (read, write int64, err error) {
+	file, err := os.Open("/proc/diskstats")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[2] != dev {
+			continue
+		}
+		read, _ = strconv.ParseInt(fields[5], 10, 64)
+		write, _ = strconv.ParseInt(fields[9], 10, 64)
+		return read, write, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+	return 0, 0, fmt.Errorf("device %s not found in /proc/diskstats", dev)
+}
+
+type procfsUptime struct{}
+
+// NewUptime returns the procfs-backed UptimeProvider.
+func NewUptime() UptimeProvider { return procfsUptime{} }
+
+func (procfsUptime) Uptime() (time.Duration, error) {
+	data, err := ioutil.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0, err
+	}
+
+	var seconds float64
+	if _, err := fmt.Sscanf(string(data), "%f", &seconds); err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+type procfsTemp struct{}
+
+// NewTemp returns the procfs-backed TempProvider.
+func NewTemp() TempProvider { return procfsTemp{} }
+
+// cpuTempLabels are the hwmon sensor labels that identify the CPU package
+// temperature, tried in order, across Intel (coretemp) and AMD (k10temp)
+// drivers.
+var cpuTempLabels = []string{"package id 0", "tdie", "tctl", "cpu"}
+
+func (procfsTemp) Temp() (float64, error) {
+	inputs, err := filepath.Glob("/sys/class/hwmon/*/temp*_input")
+	if err != nil {
+		return 0, err
+	}
+
+	for _, wantLabel := range cpuTempLabels {
+		for _, input := range inputs {
+			labelPath := strings.TrimSuffix(input, "_input") + "_label"
+			label, err := ioutil.ReadFile(labelPath)
+			if err != nil {
+				continue
+			}
+			if strings.ToLower(strings.TrimSpace(string(label))) != wantLabel {
+				continue
+			}
+
+			raw, err := ioutil.ReadFile(input)
+			if err != nil {
+				continue
+			}
+			milliC, err := strconv.ParseInt(strings.TrimSpace(string(raw)), 10, 64)
+			if err != nil {
+				continue
+			}
+			return float64(milliC) / 1000.0, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no CPU package temperature sensor found under /sys/class/hwmon")
+}
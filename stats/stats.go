@@ -0,0 +1,139 @@
+// Package stats abstracts the OS-specific system metrics dwmstatus reads,
+// so the status bar can be built for any platform with a dwm-like bar, not
+// just Linux. Each provider has a procfs-backed implementation (build tag
+// linux) and a gopsutil-backed one for everything else.
+package stats
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NetProvider reports cumulative bytes received/transmitted across the
+// interfaces of interest.
+type NetProvider interface {
+	NetUse() (rx, tx int64, err error)
+}
+
+// NetIncludeRegex and NetExcludeRegex let callers steer which network
+// interfaces count towards NetProvider.NetUse instead of a hard-coded
+// device list. If NetIncludeRegex is set, it is the only filter applied;
+// otherwise the built-in loopback/virtual exclusions apply, plus
+// NetExcludeRegex on top of those.
+var (
+	NetIncludeRegex *regexp.Regexp
+	NetExcludeRegex *regexp.Regexp
+)
+
+// defaultNetExcludePrefixes are loopback and virtual interfaces that almost
+// never carry traffic worth charting.
+var defaultNetExcludePrefixes = []string{"lo", "docker", "br-", "veth", "virbr", "tun", "tap"}
+
+// netDevWanted reports whether dev should count towards net throughput,
+// applying NetIncludeRegex/NetExcludeRegex over the built-in defaults.
+func netDevWanted(dev string) bool {
+	dev = strings.TrimSuffix(dev, ":")
+	if dev == "" {
+		return false
+	}
+	if NetIncludeRegex != nil {
+		return NetIncludeRegex.MatchString(dev)
+	}
+	for _, p := range defaultNetExcludePrefixes {
+		if strings.HasPrefix(dev, p) {
+			return false
+		}
+	}
+	return NetExcludeRegex == nil || !NetExcludeRegex.MatchString(dev)
+}
+
+// CPUProvider reports current CPU load as a percentage of total capacity.
+type CPUProvider interface {
+	CPUUse() (percent float64, err error)
+}
+
+// CPUPctProvider is an optional capability of a CPUProvider: true
+// utilization sampled from OS counters between two calls, rather than
+// load-average, with an optional per-core breakdown.
+type CPUPctProvider interface {
+	CPUPct(perCore bool) (aggregate float64, cores []float64, err error)
+}
+
+// MemProvider reports memory in use as a percentage of total.
+type MemProvider interface {
+	MemUse() (percent int, err error)
+}
+
+// PowerProvider reports battery charge percentage and whether the machine
+// is on AC power.
+type PowerProvider interface {
+	PowerUse() (percent int, plugged bool, err error)
+}
+
+// DiskStat is one mount point's capacity and throughput reading.
+type DiskStat struct {
+	UsedPercent int
+	ReadRate    int64 // bytes/sec since the previous DiskUse call, 0 on the first
+	WriteRate   int64 // bytes/sec since the previous DiskUse call, 0 on the first
+}
+
+// DiskProvider reports capacity and I/O throughput for a single mount
+// point.
+type DiskProvider interface {
+	DiskUse(mount string) (DiskStat, error)
+}
+
+// diskSample is the last read/write byte counters observed for a mount
+// point, used by both platform implementations to compute throughput
+// deltas between ticks.
+type diskSample struct {
+	readBytes, writeBytes int64
+	at                    time.Time
+}
+
+var (
+	diskSamplesMu sync.Mutex
+	diskSamples   = map[string]diskSample{}
+)
+
+// diskRate records the current byte counters for mount and returns the
+// read/write throughput since the previous call, or zero on the first call
+// for that mount.
+func diskRate(mount string, readBytes, writeBytes int64) (readRate, writeRate int64) {
+	now := time.Now()
+
+	diskSamplesMu.Lock()
+	prev, ok := diskSamples[mount]
+	diskSamples[mount] = diskSample{readBytes, writeBytes, now}
+	diskSamplesMu.Unlock()
+
+	if !ok {
+		return 0, 0
+	}
+	elapsed := now.Sub(prev.at).Seconds()
+	if elapsed <= 0 {
+		return 0, 0
+	}
+	return int64(float64(readBytes-prev.readBytes) / elapsed), int64(float64(writeBytes-prev.writeBytes) / elapsed)
+}
+
+// clearDiskSample drops the previous throughput sample for mount, so that
+// once a removed/unmounted path comes back (possibly backed by a different
+// device), the next successful read doesn't diff against stale data.
+func clearDiskSample(mount string) {
+	diskSamplesMu.Lock()
+	delete(diskSamples, mount)
+	diskSamplesMu.Unlock()
+}
+
+// UptimeProvider reports how long the system has been running.
+type UptimeProvider interface {
+	Uptime() (time.Duration, error)
+}
+
+// TempProvider reports the CPU package temperature in degrees Celsius.
+type TempProvider interface {
+	Temp() (celsius float64, err error)
+}
@@ -0,0 +1,95 @@
+package main
+
+import "fmt"
+
+// dwmColorReset switches the status bar back to its default colorscheme.
+// It's only meaningful in dwm color-escape mode; see Theme.apply.
+const dwmColorReset = "\x01"
+
+// Theme controls how severity-colored segments (CPU, MEM, battery, temp,
+// disk) are rendered: as dwm's statuscolors escape bytes by default, or as
+// pango markup when Pango is set (for bars like lemonbar that read it).
+// The two color sets are kept separate because they're different color
+// spaces: escape bytes select a colorscheme index baked into dwm's config,
+// while pango wants a literal color value.
+type Theme struct {
+	Pango bool
+
+	// OK, Warn, and Crit are dwm statuscolors escape bytes, used when
+	// Pango is false.
+	OK, Warn, Crit string
+
+	// PangoOK, PangoWarn, and PangoCrit are pango-compatible colors (e.g.
+	// "#rrggbb"), used when Pango is true.
+	PangoOK, PangoWarn, PangoCrit string
+}
+
+// theme is the active Theme; main wires up Pango from the --pango flag.
+var theme = Theme{
+	Pango: false,
+	OK:    "\x02",
+	Warn:  "\x03",
+	Crit:  "\x04",
+
+	PangoOK:   "#8fbc8f",
+	PangoWarn: "#e5c07b",
+	PangoCrit: "#e06c75",
+}
+
+// apply wraps text in the color for level.
+func (t Theme) apply(text string, level severityLevel) string {
+	if t.Pango {
+		color := t.PangoOK
+		switch level {
+		case sevWarn:
+			color = t.PangoWarn
+		case sevCrit:
+			color = t.PangoCrit
+		}
+		return fmt.Sprintf(`<span foreground="%s">%s</span>`, color, text)
+	}
+
+	color := t.OK
+	switch level {
+	case sevWarn:
+		color = t.Warn
+	case sevCrit:
+		color = t.Crit
+	}
+	return color + text + dwmColorReset
+}
+
+// severityLevel is how alarming a segment's current value is.
+type severityLevel int
+
+const (
+	sevOK severityLevel = iota
+	sevWarn
+	sevCrit
+)
+
+// severityHigh rates a metric where a HIGH value is bad (CPU, MEM, temp,
+// disk usage): crit at/above crit, warn at/above warn, ok otherwise.
+func severityHigh(value, warn, crit float64) severityLevel {
+	switch {
+	case value >= crit:
+		return sevCrit
+	case value >= warn:
+		return sevWarn
+	default:
+		return sevOK
+	}
+}
+
+// severityLow rates a metric where a LOW value is bad (battery remaining):
+// crit at/below crit, warn at/below warn, ok otherwise.
+func severityLow(value, warn, crit float64) severityLevel {
+	switch {
+	case value <= crit:
+		return sevCrit
+	case value <= warn:
+		return sevWarn
+	default:
+		return sevOK
+	}
+}